@@ -1,75 +1,237 @@
 package logrus_rollingfile_hook
 
 import (
-	"os"
 	"compress/gzip"
+	"encoding/json"
+	"github.com/dsnet/compress/bzip2"
+	"github.com/klauspost/compress/zstd"
 	"io"
+	"os"
+	"path/filepath"
+	"time"
 )
 
 const (
-	GzipSuffix = ".gz"
+	GzipSuffix  = ".gz"
+	ZstdSuffix  = ".zst"
+	Bzip2Suffix = ".bz2"
 )
 
-type ArchiveFunc func(fileName string) error
+// Compressor compresses a single rotated log file into a new archive format.
+type Compressor interface {
+	// Extension returns the file suffix this compressor produces, e.g. ".gz".
+	Extension() string
+
+	// Compress reads src and writes the compressed result to dst.
+	Compress(src io.Reader, dst io.Writer) error
+}
+
+// levelSetter is implemented by built-in compressors that support a
+// configurable compression level via CompressionLevel.
+type levelSetter interface {
+	withLevel(level int) Compressor
+}
 
-// Archiver map used for finding an archive function from a given suffix.
-var Archivers = map[string]ArchiveFunc{
-	GzipSuffix: gzipArchiveAndDelete,
+// ArchiveMetadata describes the coverage window of a log file being
+// archived, so compressors that support it can embed it in the archive.
+type ArchiveMetadata struct {
+	FirstTime time.Time
+	LastTime  time.Time
 }
 
-// Gzip file.
-func gzipArchive(fileName string) error {
-	gzFileName := fileName + GzipSuffix
+// MetadataCompressor is implemented by compressors that can embed a log
+// file's coverage window into the archive they produce.
+type MetadataCompressor interface {
+	Compressor
 
-	// Create .gz file
-	gzFile, err := os.OpenFile(gzFileName, os.O_APPEND | os.O_CREATE | os.O_WRONLY, 0664)
+	// CompressWithMetadata is like Compress but additionally receives the
+	// original file name and its coverage window, for compressors that can
+	// embed that information in the archive (e.g. gzip's header).
+	CompressWithMetadata(src io.Reader, dst io.Writer, fileName string, meta ArchiveMetadata) error
+}
+
+// Archivers is the registry used to find a Compressor for a given file
+// suffix. Built-ins are registered for gzip, zstd and bzip2; use
+// RegisterCompressor to add more or override one of these.
+var Archivers = map[string]Compressor{
+	GzipSuffix:  &gzipCompressor{},
+	ZstdSuffix:  &zstdCompressor{},
+	Bzip2Suffix: &bzip2Compressor{},
+}
+
+// RegisterCompressor makes a Compressor available for the given suffix,
+// overriding any built-in or previously registered one.
+func RegisterCompressor(suffix string, c Compressor) {
+	Archivers[suffix] = c
+}
+
+// compressAndRemove compresses fileName into fileName+c.Extension() and
+// removes the original file once the archive has been written successfully.
+// meta is passed through to c when it implements MetadataCompressor.
+func compressAndRemove(fileName string, c Compressor, meta ArchiveMetadata) error {
+	dstFile, err := os.OpenFile(fileName+c.Extension(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0664)
 
 	if err != nil {
 		return err
 	}
 
-	defer gzFile.Close()
+	defer dstFile.Close()
 
-	// Create gzip writer
-	writer := gzip.NewWriter(gzFile)
+	srcFile, err := os.Open(fileName)
 
-	// Open original file for reading
-	oldFile, err := os.Open(fileName)
+	if err != nil {
+		return err
+	}
+
+	defer srcFile.Close()
+
+	if mc, ok := c.(MetadataCompressor); ok {
+		err = mc.CompressWithMetadata(srcFile, dstFile, fileName, meta)
+	} else {
+		err = c.Compress(srcFile, dstFile)
+	}
 
 	if err != nil {
 		return err
 	}
 
-	defer oldFile.Close()
+	return os.Remove(fileName)
+}
 
-	// Read from original file and write to .gz file
-	_, err = io.Copy(writer, oldFile)
+// gzipCompressor implements Compressor using compress/gzip.
+type gzipCompressor struct {
+	level int
+}
+
+func (c *gzipCompressor) Extension() string {
+	return GzipSuffix
+}
+
+func (c *gzipCompressor) Compress(src io.Reader, dst io.Writer) error {
+	level := c.level
+
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+
+	writer, err := gzip.NewWriterLevel(dst, level)
 
 	if err != nil {
 		return err
 	}
 
-	writer.Flush()
-	writer.Close()
+	if _, err := io.Copy(writer, src); err != nil {
+		writer.Close()
+		return err
+	}
+
+	return writer.Close()
+}
 
-	return nil
+func (c *gzipCompressor) withLevel(level int) Compressor {
+	return &gzipCompressor{level: level}
 }
 
-// Gzip file and delete.
-func gzipArchiveAndDelete(fileName string) error {
-	// Gzip file
-	err := gzipArchive(fileName)
+// CompressWithMetadata is like Compress, but additionally populates the
+// gzip header with the rotated file's name and coverage window, following
+// the pattern docker's jsonfilelog uses for compressed rotated logs.
+func (c *gzipCompressor) CompressWithMetadata(src io.Reader, dst io.Writer, fileName string, meta ArchiveMetadata) error {
+	level := c.level
+
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+
+	writer, err := gzip.NewWriterLevel(dst, level)
 
 	if err != nil {
 		return err
 	}
 
-	// Delete file
-	err = os.Remove(fileName)
+	writer.Header.Name = filepath.Base(fileName)
+	writer.Header.ModTime = time.Now()
+
+	comment, err := json.Marshal(struct {
+		FirstTime time.Time `json:"firstTime"`
+		LastTime  time.Time `json:"lastTime"`
+	}{FirstTime: meta.FirstTime, LastTime: meta.LastTime})
+
+	if err == nil {
+		writer.Header.Comment = string(comment)
+	}
+
+	if _, err := io.Copy(writer, src); err != nil {
+		writer.Close()
+		return err
+	}
+
+	return writer.Close()
+}
+
+// zstdCompressor implements Compressor using klauspost/compress/zstd.
+type zstdCompressor struct {
+	level zstd.EncoderLevel
+}
+
+func (c *zstdCompressor) Extension() string {
+	return ZstdSuffix
+}
+
+func (c *zstdCompressor) Compress(src io.Reader, dst io.Writer) error {
+	var opts []zstd.EOption
+
+	if c.level != 0 {
+		opts = append(opts, zstd.WithEncoderLevel(c.level))
+	}
+
+	writer, err := zstd.NewWriter(dst, opts...)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(writer, src); err != nil {
+		writer.Close()
+		return err
+	}
+
+	return writer.Close()
+}
+
+func (c *zstdCompressor) withLevel(level int) Compressor {
+	return &zstdCompressor{level: zstd.EncoderLevel(level)}
+}
+
+// bzip2Compressor implements Compressor using dsnet/compress/bzip2.
+type bzip2Compressor struct {
+	level int
+}
+
+func (c *bzip2Compressor) Extension() string {
+	return Bzip2Suffix
+}
+
+func (c *bzip2Compressor) Compress(src io.Reader, dst io.Writer) error {
+	level := c.level
+
+	if level == 0 {
+		level = bzip2.DefaultCompression
+	}
+
+	writer, err := bzip2.NewWriter(dst, &bzip2.WriterConfig{Level: level})
 
 	if err != nil {
 		return err
 	}
 
-	return nil
+	if _, err := io.Copy(writer, src); err != nil {
+		writer.Close()
+		return err
+	}
+
+	return writer.Close()
+}
+
+func (c *bzip2Compressor) withLevel(level int) Compressor {
+	return &bzip2Compressor{level: level}
 }
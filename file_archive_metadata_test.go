@@ -0,0 +1,103 @@
+package logrus_rollingfile_hook
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+)
+
+// TestGzipCompressWithMetadata checks that CompressWithMetadata embeds the
+// rotated file's name and coverage window in the gzip header, per chunk0-7.
+func TestGzipCompressWithMetadata(t *testing.T) {
+	want := []byte("the quick brown fox jumps over the lazy dog\n")
+
+	firstTime := time.Date(2015, time.December, 31, 23, 0, 0, 0, time.UTC)
+	lastTime := time.Date(2015, time.December, 31, 23, 59, 1, 0, time.UTC)
+
+	var buf bytes.Buffer
+	c := &gzipCompressor{}
+
+	err := c.CompressWithMetadata(bytes.NewReader(want), &buf, "/var/log/app.log", ArchiveMetadata{
+		FirstTime: firstTime,
+		LastTime:  lastTime,
+	})
+
+	if err != nil {
+		t.Fatalf("CompressWithMetadata: %v", err)
+	}
+
+	reader, err := gzip.NewReader(&buf)
+
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %v", err)
+	}
+
+	if reader.Name != "app.log" {
+		t.Errorf("gzip header Name = %q, want %q", reader.Name, "app.log")
+	}
+
+	if reader.ModTime.IsZero() {
+		t.Error("gzip header ModTime was not set")
+	}
+
+	var comment struct {
+		FirstTime time.Time `json:"firstTime"`
+		LastTime  time.Time `json:"lastTime"`
+	}
+
+	if err := json.Unmarshal([]byte(reader.Comment), &comment); err != nil {
+		t.Fatalf("unmarshal gzip header Comment: %v", err)
+	}
+
+	if !comment.FirstTime.Equal(firstTime) {
+		t.Errorf("comment.FirstTime = %v, want %v", comment.FirstTime, firstTime)
+	}
+
+	if !comment.LastTime.Equal(lastTime) {
+		t.Errorf("comment.LastTime = %v, want %v", comment.LastTime, lastTime)
+	}
+}
+
+// TestCompressAndRemoveUsesMetadata checks that compressAndRemove routes
+// through CompressWithMetadata for compressors that implement
+// MetadataCompressor, per chunk0-7.
+func TestCompressAndRemoveUsesMetadata(t *testing.T) {
+	dir := t.TempDir()
+	fileName := dir + "/app.log"
+
+	if err := os.WriteFile(fileName, []byte("hello\n"), 0664); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	meta := ArchiveMetadata{
+		FirstTime: time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC),
+		LastTime:  time.Date(2020, time.January, 1, 1, 0, 0, 0, time.UTC),
+	}
+
+	if err := compressAndRemove(fileName, &gzipCompressor{}, meta); err != nil {
+		t.Fatalf("compressAndRemove: %v", err)
+	}
+
+	archived, err := os.ReadFile(fileName + GzipSuffix)
+
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(archived))
+
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %v", err)
+	}
+
+	if reader.Name != "app.log" {
+		t.Errorf("gzip header Name = %q, want %q", reader.Name, "app.log")
+	}
+
+	if reader.Comment == "" {
+		t.Error("expected gzip header Comment to carry the metadata, got empty string")
+	}
+}
@@ -0,0 +1,88 @@
+package logrus_rollingfile_hook
+
+import (
+	"bytes"
+	stdbzip2 "compress/bzip2"
+	"compress/gzip"
+	"io"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// TestCompressorRoundTrip checks every built-in Archivers entry produces an
+// archive that decompresses back to the original content, per chunk0-2.
+func TestCompressorRoundTrip(t *testing.T) {
+	want := []byte("the quick brown fox jumps over the lazy dog\n")
+
+	for suffix, compressor := range Archivers {
+		suffix, compressor := suffix, compressor
+
+		t.Run(suffix, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			if err := compressor.Compress(bytes.NewReader(want), &buf); err != nil {
+				t.Fatalf("Compress: %v", err)
+			}
+
+			var got []byte
+			var err error
+
+			switch suffix {
+			case GzipSuffix:
+				var r *gzip.Reader
+				r, err = gzip.NewReader(&buf)
+				if err == nil {
+					got, err = io.ReadAll(r)
+				}
+			case ZstdSuffix:
+				var r *zstd.Decoder
+				r, err = zstd.NewReader(&buf)
+				if err == nil {
+					defer r.Close()
+					got, err = io.ReadAll(r)
+				}
+			case Bzip2Suffix:
+				got, err = io.ReadAll(stdbzip2.NewReader(&buf))
+			default:
+				t.Fatalf("unexpected suffix %q in Archivers", suffix)
+			}
+
+			if err != nil {
+				t.Fatalf("decompress: %v", err)
+			}
+
+			if !bytes.Equal(got, want) {
+				t.Fatalf("round trip mismatch: got %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+// nullCompressor is a test-only Compressor that passes data through
+// unchanged, used to exercise RegisterCompressor.
+type nullCompressor struct{}
+
+func (nullCompressor) Extension() string {
+	return ".null"
+}
+
+func (nullCompressor) Compress(src io.Reader, dst io.Writer) error {
+	_, err := io.Copy(dst, src)
+	return err
+}
+
+func TestRegisterCompressor(t *testing.T) {
+	RegisterCompressor(".null", nullCompressor{})
+	defer delete(Archivers, ".null")
+
+	c, ok := Archivers[".null"]
+
+	if !ok {
+		t.Fatal("RegisterCompressor did not add the compressor to Archivers")
+	}
+
+	if c.Extension() != ".null" {
+		t.Fatalf("Extension() = %q, want %q", c.Extension(), ".null")
+	}
+}
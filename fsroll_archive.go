@@ -0,0 +1,173 @@
+package fsrollhook
+
+import (
+	"compress/gzip"
+	"github.com/dsnet/compress/bzip2"
+	"github.com/klauspost/compress/zstd"
+	"io"
+	"os"
+)
+
+const (
+	GzipSuffix  = ".gz"
+	ZstdSuffix  = ".zst"
+	Bzip2Suffix = ".bz2"
+)
+
+// Compressor compresses a single rotated log file into a new archive format.
+type Compressor interface {
+	// Extension returns the file suffix this compressor produces, e.g. ".gz".
+	Extension() string
+
+	// Compress reads src and writes the compressed result to dst.
+	Compress(src io.Reader, dst io.Writer) error
+}
+
+// levelSetter is implemented by built-in compressors that support a
+// configurable compression level via CompressionLevel.
+type levelSetter interface {
+	withLevel(level int) Compressor
+}
+
+// Archivers is the registry used to find a Compressor for a given file
+// suffix. Built-ins are registered for gzip, zstd and bzip2; use
+// RegisterCompressor to add more or override one of these.
+var Archivers = map[string]Compressor{
+	GzipSuffix:  &gzipCompressor{},
+	ZstdSuffix:  &zstdCompressor{},
+	Bzip2Suffix: &bzip2Compressor{},
+}
+
+// RegisterCompressor makes a Compressor available for the given suffix,
+// overriding any built-in or previously registered one.
+func RegisterCompressor(suffix string, c Compressor) {
+	Archivers[suffix] = c
+}
+
+// compressAndRemove compresses fileName into fileName+c.Extension() and
+// removes the original file once the archive has been written successfully.
+func compressAndRemove(fileName string, c Compressor) error {
+	dstFile, err := os.OpenFile(fileName+c.Extension(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0664)
+
+	if err != nil {
+		return err
+	}
+
+	defer dstFile.Close()
+
+	srcFile, err := os.Open(fileName)
+
+	if err != nil {
+		return err
+	}
+
+	defer srcFile.Close()
+
+	if err := c.Compress(srcFile, dstFile); err != nil {
+		return err
+	}
+
+	return os.Remove(fileName)
+}
+
+// gzipCompressor implements Compressor using compress/gzip.
+type gzipCompressor struct {
+	level int
+}
+
+func (c *gzipCompressor) Extension() string {
+	return GzipSuffix
+}
+
+func (c *gzipCompressor) Compress(src io.Reader, dst io.Writer) error {
+	level := c.level
+
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+
+	writer, err := gzip.NewWriterLevel(dst, level)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(writer, src); err != nil {
+		writer.Close()
+		return err
+	}
+
+	return writer.Close()
+}
+
+func (c *gzipCompressor) withLevel(level int) Compressor {
+	return &gzipCompressor{level: level}
+}
+
+// zstdCompressor implements Compressor using klauspost/compress/zstd.
+type zstdCompressor struct {
+	level zstd.EncoderLevel
+}
+
+func (c *zstdCompressor) Extension() string {
+	return ZstdSuffix
+}
+
+func (c *zstdCompressor) Compress(src io.Reader, dst io.Writer) error {
+	var opts []zstd.EOption
+
+	if c.level != 0 {
+		opts = append(opts, zstd.WithEncoderLevel(c.level))
+	}
+
+	writer, err := zstd.NewWriter(dst, opts...)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(writer, src); err != nil {
+		writer.Close()
+		return err
+	}
+
+	return writer.Close()
+}
+
+func (c *zstdCompressor) withLevel(level int) Compressor {
+	return &zstdCompressor{level: zstd.EncoderLevel(level)}
+}
+
+// bzip2Compressor implements Compressor using dsnet/compress/bzip2.
+type bzip2Compressor struct {
+	level int
+}
+
+func (c *bzip2Compressor) Extension() string {
+	return Bzip2Suffix
+}
+
+func (c *bzip2Compressor) Compress(src io.Reader, dst io.Writer) error {
+	level := c.level
+
+	if level == 0 {
+		level = bzip2.DefaultCompression
+	}
+
+	writer, err := bzip2.NewWriter(dst, &bzip2.WriterConfig{Level: level})
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(writer, src); err != nil {
+		writer.Close()
+		return err
+	}
+
+	return writer.Close()
+}
+
+func (c *bzip2Compressor) withLevel(level int) Compressor {
+	return &bzip2Compressor{level: level}
+}
@@ -0,0 +1,147 @@
+package fsrollhook
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KerwinKoo/logrus"
+)
+
+// TestFireQueuePolicyDropNewest checks that Fire drops the incoming entry
+// once the queue is full, per chunk0-6.
+func TestFireQueuePolicyDropNewest(t *testing.T) {
+	hook := &FsrollHook{queue: make(chan *logrus.Entry, 2), queuePolicy: PolicyDropNewest}
+
+	for i := 0; i < 2; i++ {
+		if err := hook.Fire(&logrus.Entry{}); err != nil {
+			t.Fatalf("Fire: %v", err)
+		}
+	}
+
+	if err := hook.Fire(&logrus.Entry{}); err != nil {
+		t.Fatalf("Fire: %v", err)
+	}
+
+	if got := hook.Enqueued(); got != 2 {
+		t.Fatalf("Enqueued() = %d, want 2", got)
+	}
+
+	if got := hook.Dropped(); got != 1 {
+		t.Fatalf("Dropped() = %d, want 1", got)
+	}
+}
+
+// TestFireQueuePolicyDropOldest checks that Fire drops the oldest queued
+// entry to make room for the incoming one, per chunk0-6.
+func TestFireQueuePolicyDropOldest(t *testing.T) {
+	hook := &FsrollHook{queue: make(chan *logrus.Entry, 2), queuePolicy: PolicyDropOldest}
+
+	first := &logrus.Entry{Message: "first"}
+	second := &logrus.Entry{Message: "second"}
+	third := &logrus.Entry{Message: "third"}
+
+	for _, e := range []*logrus.Entry{first, second, third} {
+		if err := hook.Fire(e); err != nil {
+			t.Fatalf("Fire: %v", err)
+		}
+	}
+
+	if got := hook.Dropped(); got != 1 {
+		t.Fatalf("Dropped() = %d, want 1", got)
+	}
+
+	if got := hook.Enqueued(); got != 3 {
+		t.Fatalf("Enqueued() = %d, want 3", got)
+	}
+
+	remaining := []*logrus.Entry{<-hook.queue, <-hook.queue}
+
+	if remaining[0] != second || remaining[1] != third {
+		t.Fatalf("expected [second, third] to remain, got %v", remaining)
+	}
+}
+
+// TestFireQueuePolicyTimeout checks that Fire gives up and drops the entry
+// after waiting out the configured timeout, per chunk0-6.
+func TestFireQueuePolicyTimeout(t *testing.T) {
+	hook := &FsrollHook{queue: make(chan *logrus.Entry, 1), queuePolicy: PolicyTimeout(20 * time.Millisecond)}
+
+	if err := hook.Fire(&logrus.Entry{}); err != nil {
+		t.Fatalf("Fire: %v", err)
+	}
+
+	start := time.Now()
+
+	if err := hook.Fire(&logrus.Entry{}); err != nil {
+		t.Fatalf("Fire: %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Fatalf("Fire returned after %v, expected to wait out the 20ms timeout", elapsed)
+	}
+
+	if got := hook.Dropped(); got != 1 {
+		t.Fatalf("Dropped() = %d, want 1", got)
+	}
+}
+
+// slowFormatter sleeps before formatting, widening the window between an
+// entry leaving hook.queue and its write actually completing.
+type slowFormatter struct {
+	delay time.Duration
+}
+
+func (f *slowFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	time.Sleep(f.delay)
+	return []byte(entry.Message + "\n"), nil
+}
+
+// TestFlushWaitsForWriteCompletion checks that Flush doesn't return until
+// every enqueued entry has actually been written, not just dequeued, per
+// chunk0-6.
+func TestFlushWaitsForWriteCompletion(t *testing.T) {
+	dir := t.TempDir()
+	pattern := filepath.Join(dir, "app.log")
+
+	// t.TempDir() embeds numbered path components (e.g. "/001") that Go's
+	// reference-time layout would otherwise parse as format tokens (month
+	// "01", etc), mangling the written path. Force DialectStrftime so
+	// pattern is only rewritten where it contains "%", leaving the literal
+	// temp dir path intact.
+	hook, err := NewHook(
+		[]logrus.Level{logrus.InfoLevel},
+		&slowFormatter{delay: 50 * time.Millisecond},
+		pattern,
+		WithPatternDialect(DialectStrftime))
+
+	if err != nil {
+		t.Fatalf("NewHook: %v", err)
+	}
+
+	logger := logrus.New()
+	logger.Hooks.Add(hook)
+
+	for _, msg := range []string{"a", "b", "c"} {
+		logger.Info(msg)
+	}
+
+	if err := hook.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	content, err := os.ReadFile(pattern)
+
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines written by the time Flush returned, got %d: %q", len(lines), content)
+	}
+}
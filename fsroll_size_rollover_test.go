@@ -0,0 +1,56 @@
+package fsrollhook
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/KerwinKoo/logrus"
+)
+
+// TestSizeBasedRollover writes entries well past MaxSize and checks that the
+// file is shifted to name.1/name.2 and capped at MaxFiles, per chunk0-1.
+func TestSizeBasedRollover(t *testing.T) {
+	dir := t.TempDir()
+	pattern := filepath.Join(dir, "app.log")
+
+	// t.TempDir() embeds numbered path components (e.g. "/001") that Go's
+	// reference-time layout would otherwise parse as format tokens (month
+	// "01", etc), mangling the written path. Force DialectStrftime so
+	// pattern is only rewritten where it contains "%", leaving the literal
+	// temp dir path intact.
+	hook, err := NewHook(
+		[]logrus.Level{logrus.InfoLevel},
+		&logrus.JSONFormatter{},
+		pattern,
+		WithMaxSize(10),
+		WithMaxFiles(2),
+		WithPatternDialect(DialectStrftime))
+
+	if err != nil {
+		t.Fatalf("NewHook: %v", err)
+	}
+
+	logger := logrus.New()
+	logger.Hooks.Add(hook)
+
+	for i := 0; i < 5; i++ {
+		logger.Info(strings.Repeat("x", 100))
+	}
+
+	if err := hook.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	for _, suffix := range []string{"", ".1", ".2"} {
+		if _, err := os.Stat(pattern + suffix); err != nil {
+			t.Fatalf("expected %s%s to exist: %v", pattern, suffix, err)
+		}
+	}
+
+	if _, err := os.Stat(pattern + ".3"); !os.IsNotExist(err) {
+		t.Fatalf("expected %s.3 not to exist with MaxFiles=2", pattern)
+	}
+}
@@ -1,12 +1,14 @@
 package fsrollhook
 
 import (
+	"fmt"
 	"log"
 	"os"
 	"path"
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/KerwinKoo/logrus"
@@ -23,10 +25,116 @@ type FsrollHook struct {
 	timer           *time.Timer // Timer to trigger file rollover
 	queue           chan *logrus.Entry
 	mu              *sync.Mutex
+
+	// maxSize is the size in bytes the current file may reach before it is
+	// rolled over regardless of the time-based schedule. 0 disables it.
+	maxSize int64
+
+	// maxFiles caps the number of size-rollover backups (name.1 .. name.N)
+	// kept on disk. 0 means keep all backups.
+	maxFiles int
+
+	// currentSize tracks bytes written to the current file, avoiding an
+	// fstat on every write.
+	currentSize int64
+
+	// compressionLevel is passed to the Compressor used to archive rolled
+	// over files, for compressors that support one (gzip, zstd, bzip2). 0
+	// means the compressor's default.
+	compressionLevel int
+
+	// currentSymlink, if set, is atomically symlinked to the active file
+	// after every successful rollover, giving tools like "tail -F" a
+	// stable path.
+	currentSymlink string
+
+	// onError is called with any error updating currentSymlink, instead of
+	// log.Printf.
+	onError func(error)
+
+	// queuePolicy controls what Fire does when the queue is full. Defaults
+	// to PolicyBlock.
+	queuePolicy QueuePolicy
+
+	// Which dialect FileNamePattern is written in. Defaults to DialectAuto.
+	patternDialect PatternDialect
+
+	// Counters exposed via Enqueued, Dropped and WriteErrors. Updated with
+	// sync/atomic since Fire is called concurrently by logrus.
+	enqueued    uint64
+	dropped     uint64
+	writeErrors uint64
+
+	// processed counts entries writeEntry has finished handling (written or
+	// errored), so Flush can tell when it has actually caught up with
+	// Enqueued instead of just seeing an empty channel buffer.
+	processed uint64
+}
+
+// Option configures optional behavior of a FsrollHook.
+type Option func(*FsrollHook)
+
+// WithMaxSize enables size-based rollover: once the current file reaches
+// maxSize bytes, it is renamed to name.1 (shifting older backups up) and a
+// fresh file is opened in its place.
+func WithMaxSize(maxSize int64) Option {
+	return func(hook *FsrollHook) {
+		hook.maxSize = maxSize
+	}
+}
+
+// WithMaxFiles caps the number of size-rollover backups (name.1 .. name.N)
+// kept on disk; the oldest is deleted once the cap is exceeded. 0 means
+// keep all backups.
+func WithMaxFiles(maxFiles int) Option {
+	return func(hook *FsrollHook) {
+		hook.maxFiles = maxFiles
+	}
+}
+
+// WithCompressionLevel sets the compression level passed to the Compressor
+// used to archive rolled over files, for compressors that support one
+// (gzip, zstd, bzip2). Ignored for compressors that don't.
+func WithCompressionLevel(level int) Option {
+	return func(hook *FsrollHook) {
+		hook.compressionLevel = level
+	}
+}
+
+// WithQueuePolicy sets the backpressure policy Fire applies when the queue
+// is full. Defaults to PolicyBlock.
+func WithQueuePolicy(policy QueuePolicy) Option {
+	return func(hook *FsrollHook) {
+		hook.queuePolicy = policy
+	}
+}
+
+// WithCurrentSymlink keeps path symlinked to the active log file, updated
+// atomically after every rollover.
+func WithCurrentSymlink(path string) Option {
+	return func(hook *FsrollHook) {
+		hook.currentSymlink = path
+	}
+}
+
+// WithOnError registers a callback invoked with errors from updating
+// currentSymlink, instead of the default log.Printf.
+func WithOnError(onError func(error)) Option {
+	return func(hook *FsrollHook) {
+		hook.onError = onError
+	}
+}
+
+// WithPatternDialect forces FileNamePattern to be interpreted as DialectGo
+// or DialectStrftime instead of being auto-detected.
+func WithPatternDialect(dialect PatternDialect) Option {
+	return func(hook *FsrollHook) {
+		hook.patternDialect = dialect
+	}
 }
 
 // NewHook Create a new FsrollHook.
-func NewHook(levels []logrus.Level, formatter logrus.Formatter, fileNamePattern string) (*FsrollHook, error) {
+func NewHook(levels []logrus.Level, formatter logrus.Formatter, fileNamePattern string, opts ...Option) (*FsrollHook, error) {
 	hook := &FsrollHook{}
 
 	hook.levels = levels
@@ -35,6 +143,10 @@ func NewHook(levels []logrus.Level, formatter logrus.Formatter, fileNamePattern
 	hook.queue = make(chan *logrus.Entry, 1000)
 	hook.mu = &sync.Mutex{}
 
+	for _, opt := range opts {
+		opt(hook)
+	}
+
 	// Create new file
 	_, err := hook.rolloverFile()
 
@@ -63,13 +175,13 @@ func (hook *FsrollHook) rolloverAfter() time.Duration {
 	// Get the current local time
 	t := time.Now().Local()
 
-	oldFileName := t.Format(hook.FileNamePattern)
+	oldFileName := hook.formatPattern(t)
 
 	var t1 time.Time
 	var newFileName string
 
 	t1 = t.Add(time.Minute)
-	newFileName = t1.Format(hook.FileNamePattern)
+	newFileName = hook.formatPattern(t1)
 	if oldFileName != newFileName {
 		// Need to rollover per minute
 		t2 := time.Date(t1.Year(), t1.Month(), t1.Day(), t1.Hour(), t1.Minute(), 0, 0, t1.Location())
@@ -77,7 +189,7 @@ func (hook *FsrollHook) rolloverAfter() time.Duration {
 	}
 
 	t1 = t.Add(time.Hour)
-	newFileName = t1.Format(hook.FileNamePattern)
+	newFileName = hook.formatPattern(t1)
 	if oldFileName != newFileName {
 		// Need to rollover per hour
 
@@ -87,7 +199,7 @@ func (hook *FsrollHook) rolloverAfter() time.Duration {
 	}
 
 	t1 = t.AddDate(0, 0, 1)
-	newFileName = t1.Format(hook.FileNamePattern)
+	newFileName = hook.formatPattern(t1)
 	if oldFileName != newFileName {
 		// Need to rollover per day
 		t2 := time.Date(t1.Year(), t1.Month(), t1.Day(), 0, 0, 0, 0, t1.Location())
@@ -96,7 +208,7 @@ func (hook *FsrollHook) rolloverAfter() time.Duration {
 	}
 
 	t1 = t.AddDate(0, 1, 0)
-	newFileName = t1.Format(hook.FileNamePattern)
+	newFileName = hook.formatPattern(t1)
 	if oldFileName != newFileName {
 		// Need to rollover per month
 		t2 := time.Date(t1.Year(), t1.Month(), 1, 0, 0, 0, 0, t1.Location())
@@ -105,7 +217,7 @@ func (hook *FsrollHook) rolloverAfter() time.Duration {
 	}
 
 	t1 = t.AddDate(1, 0, 0)
-	newFileName = t1.Format(hook.FileNamePattern)
+	newFileName = hook.formatPattern(t1)
 	if oldFileName != newFileName {
 		// Need to rollover per year
 		t2 := time.Date(t1.Year(), 1, 1, 0, 0, 0, 0, t1.Location())
@@ -139,7 +251,7 @@ func (hook *FsrollHook) rolloverFile() (string, error) {
 	}
 
 	// Get new file name
-	newFileNameOrig := time.Now().Local().Format(hook.FileNamePattern)
+	newFileNameOrig := hook.formatPattern(time.Now().Local())
 
 	switch strings.ToLower(filepath.Ext(newFileNameOrig)) {
 	case GzipSuffix:
@@ -166,10 +278,104 @@ func (hook *FsrollHook) rolloverFile() (string, error) {
 
 	// Switch hook.file to newFile
 	hook.file = newFile
+	hook.currentSize = 0
+
+	hook.updateSymlink(newFileNameOrig)
 
 	return oldFileName, nil
 }
 
+// rolloverForSize rolls the current file over because it reached MaxSize,
+// without waiting for the next time-based rollover. The current file is
+// shifted to name.1 (older backups shifting up to name.2, name.3, ...) and
+// a fresh file is opened under the original name.
+// Caller must hold mu.
+func (hook *FsrollHook) rolloverForSize() error {
+	fileName := hook.file.Name()
+
+	if err := hook.file.Close(); err != nil {
+		log.Printf("Error on closing file [%s]: %v\n", fileName, err)
+	}
+
+	if err := shiftBackups(fileName, hook.maxFiles); err != nil {
+		return err
+	}
+
+	newFile, err := os.OpenFile(fileName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0664)
+
+	if err != nil {
+		return err
+	}
+
+	hook.file = newFile
+	hook.currentSize = 0
+
+	hook.updateSymlink(fileName)
+
+	return nil
+}
+
+// updateSymlink atomically points currentSymlink at target, by symlinking to
+// a temp path and renaming over it. A no-op if currentSymlink isn't set, and
+// silently non-fatal (reported via onError) if os.Symlink isn't supported on
+// this platform. Caller must hold mu.
+func (hook *FsrollHook) updateSymlink(target string) {
+	if hook.currentSymlink == "" {
+		return
+	}
+
+	tmp := hook.currentSymlink + ".tmp"
+
+	os.Remove(tmp)
+
+	if err := os.Symlink(target, tmp); err != nil {
+		hook.reportError(err)
+		return
+	}
+
+	if err := os.Rename(tmp, hook.currentSymlink); err != nil {
+		hook.reportError(err)
+	}
+}
+
+// reportError surfaces err via onError if set, falling back to log.Printf.
+func (hook *FsrollHook) reportError(err error) {
+	if hook.onError != nil {
+		hook.onError(err)
+		return
+	}
+
+	log.Printf("Error updating current symlink: %v\n", err)
+}
+
+// shiftBackups renames fileName to fileName.1, pushing any existing
+// fileName.1 .. fileName.N-1 up by one. If maxFiles > 0, fileName.maxFiles
+// (the oldest) is deleted first. maxFiles <= 0 means keep all backups.
+func shiftBackups(fileName string, maxFiles int) error {
+	if maxFiles > 0 {
+		oldest := fmt.Sprintf("%s.%d", fileName, maxFiles)
+
+		if _, err := os.Stat(oldest); err == nil {
+			if err := os.Remove(oldest); err != nil {
+				return err
+			}
+		}
+
+		for i := maxFiles - 1; i >= 1; i-- {
+			src := fmt.Sprintf("%s.%d", fileName, i)
+			dst := fmt.Sprintf("%s.%d", fileName, i+1)
+
+			if _, err := os.Stat(src); err == nil {
+				if err := os.Rename(src, dst); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return os.Rename(fileName, fileName+".1")
+}
+
 // Reset timer and archive old file if needed.
 func (hook *FsrollHook) resetTimer() {
 	// Roll over file
@@ -198,13 +404,21 @@ func (hook *FsrollHook) resetTimer() {
 
 // Archive old file if needed.
 func (hook *FsrollHook) archiveOldFile(fileName string) {
-	if archive, ok := Archivers[strings.ToLower(filepath.Ext(hook.FileNamePattern))]; ok {
-		err := archive(fileName)
+	compressor, ok := Archivers[strings.ToLower(filepath.Ext(hook.FileNamePattern))]
 
-		if err != nil {
-			log.Printf("Error on archiving file [%s]: %v\n", fileName, err)
+	if !ok {
+		return
+	}
+
+	if hook.compressionLevel != 0 {
+		if ls, ok := compressor.(levelSetter); ok {
+			compressor = ls.withLevel(hook.compressionLevel)
 		}
 	}
+
+	if err := compressAndRemove(fileName, compressor); err != nil {
+		log.Printf("Error on archiving file [%s]: %v\n", fileName, err)
+	}
 }
 
 // Write logrus.Entry to file.
@@ -245,11 +459,20 @@ func (hook *FsrollHook) write(entry *logrus.Entry) error {
 		}
 
 		// Writing to file
-		_, err = hook.file.Write(b)
+		n, err := hook.file.Write(b)
 
 		if err != nil {
 			return err
 		}
+
+		hook.currentSize += int64(n)
+
+		// Roll over on size if configured, sharing mu with the time-based rollover
+		if hook.maxSize > 0 && hook.currentSize >= hook.maxSize {
+			if err := hook.rolloverForSize(); err != nil {
+				log.Printf("Error on size-based rollover: %v\n", err)
+			}
+		}
 	}
 
 	return nil
@@ -263,9 +486,13 @@ func (hook *FsrollHook) writeEntry() {
 		err := hook.write(entry)
 
 		if err != nil {
+			atomic.AddUint64(&hook.writeErrors, 1)
+			atomic.AddUint64(&hook.processed, 1)
 			log.Printf("Error on writing to file: %v\n", err)
 			return
 		}
+
+		atomic.AddUint64(&hook.processed, 1)
 	}
 }
 
@@ -276,7 +503,46 @@ func (hook *FsrollHook) Levels() []logrus.Level {
 
 // Fire logrus fire
 func (hook *FsrollHook) Fire(entry *logrus.Entry) error {
-	hook.queue <- entry
+	switch hook.queuePolicy.kind {
+	case queuePolicyDropNewest:
+		select {
+		case hook.queue <- entry:
+			atomic.AddUint64(&hook.enqueued, 1)
+		default:
+			atomic.AddUint64(&hook.dropped, 1)
+		}
+
+	case queuePolicyDropOldest:
+		select {
+		case hook.queue <- entry:
+			atomic.AddUint64(&hook.enqueued, 1)
+		default:
+			select {
+			case <-hook.queue:
+				atomic.AddUint64(&hook.dropped, 1)
+			default:
+			}
+
+			select {
+			case hook.queue <- entry:
+				atomic.AddUint64(&hook.enqueued, 1)
+			default:
+				atomic.AddUint64(&hook.dropped, 1)
+			}
+		}
+
+	case queuePolicyTimeout:
+		select {
+		case hook.queue <- entry:
+			atomic.AddUint64(&hook.enqueued, 1)
+		case <-time.After(hook.queuePolicy.timeout):
+			atomic.AddUint64(&hook.dropped, 1)
+		}
+
+	default:
+		hook.queue <- entry
+		atomic.AddUint64(&hook.enqueued, 1)
+	}
 
 	return nil
 }
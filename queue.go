@@ -0,0 +1,81 @@
+package logrus_rollingfile_hook
+
+import (
+	"context"
+	"time"
+)
+
+type queuePolicyKind int
+
+const (
+	queuePolicyBlock queuePolicyKind = iota
+	queuePolicyDropNewest
+	queuePolicyDropOldest
+	queuePolicyTimeout
+)
+
+// QueuePolicy controls what Fire does when the queue is full.
+type QueuePolicy struct {
+	kind    queuePolicyKind
+	timeout time.Duration
+}
+
+// PolicyBlock blocks the caller until there's room in the queue. This is the
+// default, and matches the hook's original behavior.
+var PolicyBlock = QueuePolicy{kind: queuePolicyBlock}
+
+// PolicyDropNewest drops the incoming entry when the queue is full, leaving
+// already-queued entries untouched.
+var PolicyDropNewest = QueuePolicy{kind: queuePolicyDropNewest}
+
+// PolicyDropOldest drops the oldest queued entry to make room for the
+// incoming one when the queue is full.
+var PolicyDropOldest = QueuePolicy{kind: queuePolicyDropOldest}
+
+// PolicyTimeout blocks the caller for at most d waiting for room in the
+// queue, dropping the incoming entry if it times out.
+func PolicyTimeout(d time.Duration) QueuePolicy {
+	return QueuePolicy{kind: queuePolicyTimeout, timeout: d}
+}
+
+// WithQueuePolicy sets the backpressure policy Fire applies when the queue
+// is full. Defaults to PolicyBlock.
+func WithQueuePolicy(policy QueuePolicy) Option {
+	return func(hook *TimeBasedRollingFileHook) {
+		hook.queuePolicy = policy
+	}
+}
+
+// Dropped returns the number of entries dropped so far because of the
+// configured QueuePolicy.
+func (hook *TimeBasedRollingFileHook) Dropped() uint64 {
+	return loadCounter(&hook.dropped)
+}
+
+// Enqueued returns the number of entries successfully enqueued so far.
+func (hook *TimeBasedRollingFileHook) Enqueued() uint64 {
+	return loadCounter(&hook.enqueued)
+}
+
+// WriteErrors returns the number of errors encountered writing entries to
+// the current file so far.
+func (hook *TimeBasedRollingFileHook) WriteErrors() uint64 {
+	return loadCounter(&hook.writeErrors)
+}
+
+// Flush waits until every entry enqueued so far has actually been written
+// (or failed to write), or until ctx is done. Useful during graceful
+// shutdown to ensure queued entries are written before exiting.
+func (hook *TimeBasedRollingFileHook) Flush(ctx context.Context) error {
+	target := loadCounter(&hook.enqueued)
+
+	for loadCounter(&hook.processed) < target {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package logrus_rollingfile_hook
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// layoutTokens are the Go reference-time layout tokens recognized when
+// translating fileNamePattern into a glob pattern for the retention
+// sweeper, each listed before any shorter token it contains so e.g. "2006"
+// isn't partially matched by "06".
+var layoutTokens = []string{
+	"2006", "January", "Monday", "15", "Jan", "Mon",
+	"01", "02", "03", "04", "05", "06", "PM", "pm",
+}
+
+// layoutToGlob turns a fileNamePattern using Go's reference-time layout into
+// a glob pattern by replacing each of layoutTokens with "*". Other layout
+// elements (e.g. "1", "2", "_2", "MST") are left as literal text, so files
+// rendered from them are matched only where the rendered text equals it.
+func layoutToGlob(pattern string) string {
+	glob := pattern
+
+	for _, token := range layoutTokens {
+		glob = strings.ReplaceAll(glob, token, "*")
+	}
+
+	return glob
+}
+
+// runSweeper runs the retention sweep every time it's triggered, until
+// sweepTrigger is closed by Close.
+func (hook *TimeBasedRollingFileHook) runSweeper() {
+	defer close(hook.sweepDone)
+
+	for range hook.sweepTrigger {
+		hook.sweep()
+	}
+}
+
+// triggerSweep asks the sweeper to run a sweep soon. Non-blocking: if a
+// sweep is already pending, this is a no-op.
+func (hook *TimeBasedRollingFileHook) triggerSweep() {
+	select {
+	case hook.sweepTrigger <- struct{}{}:
+	default:
+	}
+}
+
+// sweep deletes archived logs older than maxAge or beyond maxBackups,
+// matching the directory tree implied by fileNamePattern.
+func (hook *TimeBasedRollingFileHook) sweep() {
+	if hook.maxAge <= 0 && hook.maxBackups <= 0 {
+		return
+	}
+
+	glob := hook.patternGlob()
+
+	matches, err := filepath.Glob(glob)
+
+	if err != nil {
+		log.Printf("Error on globbing retention pattern [%s]: %v\n", glob, err)
+		return
+	}
+
+	// Also sweep compressed archives and size-rollover backups of matched files
+	for suffix := range Archivers {
+		if archived, err := filepath.Glob(glob + suffix); err == nil {
+			matches = append(matches, archived...)
+		}
+	}
+
+	if backups, err := filepath.Glob(glob + ".[0-9]*"); err == nil {
+		matches = append(matches, backups...)
+	}
+
+	activeFile := ""
+
+	hook.mu.Lock()
+	if hook.file != nil {
+		activeFile = hook.file.Name()
+	}
+	hook.mu.Unlock()
+
+	type candidate struct {
+		name    string
+		modTime time.Time
+	}
+
+	var candidates []candidate
+
+	for _, name := range matches {
+		if name == activeFile {
+			continue
+		}
+
+		info, err := os.Stat(name)
+
+		if err != nil {
+			continue
+		}
+
+		candidates = append(candidates, candidate{name: name, modTime: info.ModTime()})
+	}
+
+	// Newest first, so index position doubles as the backup count for MaxBackups
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].modTime.After(candidates[j].modTime)
+	})
+
+	now := time.Now()
+
+	for i, c := range candidates {
+		expired := hook.maxAge > 0 && now.Sub(c.modTime) > hook.maxAge
+		overCount := hook.maxBackups > 0 && i >= hook.maxBackups
+
+		if expired || overCount {
+			if err := os.Remove(c.name); err != nil {
+				log.Printf("Error on removing expired log [%s]: %v\n", c.name, err)
+			}
+		}
+	}
+}
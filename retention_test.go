@@ -0,0 +1,135 @@
+package logrus_rollingfile_hook
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TestRetentionSweepMaxBackups checks that sweep keeps only the newest
+// MaxBackups archives and removes the rest, per chunk0-3.
+func TestRetentionSweepMaxBackups(t *testing.T) {
+	dir := t.TempDir()
+	pattern := filepath.Join(dir, "app.log")
+
+	hook, err := NewTimeBasedRollingFileHook("retention-backups-test",
+		[]logrus.Level{logrus.InfoLevel},
+		&logrus.JSONFormatter{},
+		pattern,
+		WithMaxBackups(2))
+
+	if err != nil {
+		t.Fatalf("NewTimeBasedRollingFileHook: %v", err)
+	}
+
+	defer hook.Close()
+
+	backups := []string{pattern + ".1", pattern + ".2", pattern + ".3", pattern + ".4"}
+
+	for i, name := range backups {
+		if err := os.WriteFile(name, []byte("backup"), 0664); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+
+		// Newest first: backups[0] is newest, backups[3] is oldest.
+		modTime := time.Now().Add(-time.Duration(i) * time.Hour)
+
+		if err := os.Chtimes(name, modTime, modTime); err != nil {
+			t.Fatalf("Chtimes: %v", err)
+		}
+	}
+
+	hook.sweep()
+
+	for _, name := range backups[:2] {
+		if _, err := os.Stat(name); err != nil {
+			t.Fatalf("expected %s to survive MaxBackups=2: %v", name, err)
+		}
+	}
+
+	for _, name := range backups[2:] {
+		if _, err := os.Stat(name); !os.IsNotExist(err) {
+			t.Fatalf("expected %s to be swept by MaxBackups=2", name)
+		}
+	}
+}
+
+// TestRetentionSweepMaxAge checks that sweep removes archives older than
+// MaxAge regardless of count, per chunk0-3.
+func TestRetentionSweepMaxAge(t *testing.T) {
+	dir := t.TempDir()
+	pattern := filepath.Join(dir, "app.log")
+
+	hook, err := NewTimeBasedRollingFileHook("retention-age-test",
+		[]logrus.Level{logrus.InfoLevel},
+		&logrus.JSONFormatter{},
+		pattern,
+		WithMaxAge(time.Hour))
+
+	if err != nil {
+		t.Fatalf("NewTimeBasedRollingFileHook: %v", err)
+	}
+
+	defer hook.Close()
+
+	fresh := pattern + ".1"
+	stale := pattern + ".2"
+
+	for _, name := range []string{fresh, stale} {
+		if err := os.WriteFile(name, []byte("backup"), 0664); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+
+	if err := os.Chtimes(stale, time.Now().Add(-2*time.Hour), time.Now().Add(-2*time.Hour)); err != nil {
+		t.Fatalf("Chtimes: %v", err)
+	}
+
+	hook.sweep()
+
+	if _, err := os.Stat(fresh); err != nil {
+		t.Fatalf("expected %s to survive MaxAge: %v", fresh, err)
+	}
+
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Fatalf("expected %s to be swept by MaxAge", stale)
+	}
+}
+
+// TestClose checks that Close stops the timer and sweeper cleanly, and that
+// Fire calls arriving after Close don't panic, per chunk0-3.
+func TestClose(t *testing.T) {
+	dir := t.TempDir()
+	pattern := filepath.Join(dir, "app.log")
+
+	hook, err := NewTimeBasedRollingFileHook("close-test",
+		[]logrus.Level{logrus.InfoLevel},
+		&logrus.JSONFormatter{},
+		pattern)
+
+	if err != nil {
+		t.Fatalf("NewTimeBasedRollingFileHook: %v", err)
+	}
+
+	if err := hook.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			t.Fatalf("Fire after Close panicked: %v", r)
+		}
+	}()
+
+	if err := hook.Fire(&logrus.Entry{Message: "after close"}); err != nil {
+		t.Fatalf("Fire after Close: %v", err)
+	}
+
+	if err := hook.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush after Close: %v", err)
+	}
+}
@@ -1,12 +1,14 @@
 package logrus_rollingfile_hook
 
 import (
+	"fmt"
 	"github.com/sirupsen/logrus"
 	"log"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -32,10 +34,155 @@ type TimeBasedRollingFileHook struct {
 	queue chan *logrus.Entry
 
 	mu *sync.Mutex
+
+	// Maximum size in bytes the current file may reach before it is rolled
+	// over regardless of the time-based schedule. 0 disables size-based rollover.
+	maxSize int64
+
+	// Maximum number of rolled-over backups (name.1 .. name.N) to keep.
+	// 0 means keep all backups.
+	maxFiles int
+
+	// Bytes written to the current file so far, tracked instead of fstat'ing
+	// on every write.
+	currentSize int64
+
+	// Compression level passed to the Compressor used to archive rolled
+	// over files, when it supports one. 0 means the compressor's default.
+	compressionLevel int
+
+	// Retention: delete archived logs older than maxAge. 0 disables this.
+	maxAge time.Duration
+
+	// Retention: keep at most maxBackups archived logs. 0 disables this.
+	maxBackups int
+
+	// Signals a sweep of the retention subsystem; buffered so a rollover
+	// doesn't block waiting for a previous sweep to finish.
+	sweepTrigger chan struct{}
+
+	// Closed once the sweeper goroutine has returned.
+	sweepDone chan struct{}
+
+	// Closed once writeEntry has drained hook.queue.
+	writeDone chan struct{}
+
+	// Guards closed against concurrent Fire calls, so Close can close
+	// hook.queue only once no Fire is still sending on it.
+	closeMu sync.RWMutex
+
+	// Set under closeMu.Lock by Close; checked under closeMu.RLock by Fire
+	// so a Fire racing with Close never sends on a closed channel.
+	closed bool
+
+	// Which dialect fileNamePattern is written in. Defaults to DialectAuto.
+	patternDialect PatternDialect
+
+	// If set, this path is atomically symlinked to the active file after
+	// every successful rollover, giving tools like "tail -F" a stable path.
+	currentSymlink string
+
+	// Called with any error updating currentSymlink, instead of log.Printf.
+	onError func(error)
+
+	// Backpressure policy Fire applies when the queue is full. Defaults to
+	// PolicyBlock.
+	queuePolicy QueuePolicy
+
+	// Counters exposed via Enqueued, Dropped and WriteErrors. Updated with
+	// sync/atomic since Fire is called concurrently by logrus.
+	enqueued    uint64
+	dropped     uint64
+	writeErrors uint64
+
+	// processed counts entries writeEntry has finished handling (written or
+	// errored), so Flush can tell when it has actually caught up with
+	// Enqueued instead of just seeing an empty channel buffer.
+	processed uint64
+
+	// Coverage window of the current file: set on first write, cleared on
+	// rollover, and passed to the archiver as ArchiveMetadata.
+	firstWriteTime time.Time
+	lastWriteTime  time.Time
+}
+
+// loadCounter atomically reads one of the hook's counters.
+func loadCounter(counter *uint64) uint64 {
+	return atomic.LoadUint64(counter)
+}
+
+// Option configures optional behavior of a TimeBasedRollingFileHook.
+type Option func(*TimeBasedRollingFileHook)
+
+// WithMaxSize enables size-based rollover: once the current file reaches
+// maxSize bytes, it is renamed to name.1 (shifting older backups up) and a
+// fresh file is opened in its place.
+func WithMaxSize(maxSize int64) Option {
+	return func(hook *TimeBasedRollingFileHook) {
+		hook.maxSize = maxSize
+	}
+}
+
+// WithMaxFiles caps the number of size-rollover backups (name.1 .. name.N)
+// kept on disk; the oldest is deleted once the cap is exceeded. 0 means
+// keep all backups.
+func WithMaxFiles(maxFiles int) Option {
+	return func(hook *TimeBasedRollingFileHook) {
+		hook.maxFiles = maxFiles
+	}
+}
+
+// WithCompressionLevel sets the compression level passed to the Compressor
+// used to archive rolled over files, for compressors that support one
+// (gzip, zstd, bzip2). Ignored for compressors that don't.
+func WithCompressionLevel(level int) Option {
+	return func(hook *TimeBasedRollingFileHook) {
+		hook.compressionLevel = level
+	}
+}
+
+// WithMaxAge enables the retention sweeper to delete archived logs older
+// than maxAge. 0 disables age-based retention.
+func WithMaxAge(maxAge time.Duration) Option {
+	return func(hook *TimeBasedRollingFileHook) {
+		hook.maxAge = maxAge
+	}
+}
+
+// WithMaxBackups caps the number of archived logs the retention sweeper
+// keeps; the oldest beyond the cap are deleted. 0 disables this cap.
+func WithMaxBackups(maxBackups int) Option {
+	return func(hook *TimeBasedRollingFileHook) {
+		hook.maxBackups = maxBackups
+	}
+}
+
+// WithPatternDialect forces fileNamePattern to be interpreted as DialectGo
+// or DialectStrftime instead of being auto-detected.
+func WithPatternDialect(dialect PatternDialect) Option {
+	return func(hook *TimeBasedRollingFileHook) {
+		hook.patternDialect = dialect
+	}
+}
+
+// WithCurrentSymlink keeps path symlinked to the active log file, updated
+// atomically after every rollover.
+func WithCurrentSymlink(path string) Option {
+	return func(hook *TimeBasedRollingFileHook) {
+		hook.currentSymlink = path
+	}
+}
+
+// WithOnError registers a callback invoked with errors from updating
+// currentSymlink, instead of the default log.Printf.
+func WithOnError(onError func(error)) Option {
+	return func(hook *TimeBasedRollingFileHook) {
+		hook.onError = onError
+	}
 }
 
 // Create a new TimeBasedRollingFileHook.
-func NewTimeBasedRollingFileHook(id string, levels []logrus.Level, formatter logrus.Formatter, fileNamePattern string) (*TimeBasedRollingFileHook, error) {
+func NewTimeBasedRollingFileHook(id string, levels []logrus.Level, formatter logrus.Formatter, fileNamePattern string, opts ...Option) (*TimeBasedRollingFileHook, error) {
 	hook := &TimeBasedRollingFileHook{}
 
 	hook.id = id
@@ -44,9 +191,16 @@ func NewTimeBasedRollingFileHook(id string, levels []logrus.Level, formatter log
 	hook.fileNamePattern = fileNamePattern
 	hook.queue = make(chan *logrus.Entry, 1000)
 	hook.mu = &sync.Mutex{}
+	hook.sweepTrigger = make(chan struct{}, 1)
+	hook.sweepDone = make(chan struct{})
+	hook.writeDone = make(chan struct{})
+
+	for _, opt := range opts {
+		opt(hook)
+	}
 
 	// Create new file
-	_, err := hook.rolloverFile()
+	_, _, err := hook.rolloverFile()
 
 	if err != nil {
 		log.Printf("Error on creating new file: %v\n", err)
@@ -62,6 +216,10 @@ func NewTimeBasedRollingFileHook(id string, levels []logrus.Level, formatter log
 	// Write logrus.Entry
 	go hook.writeEntry()
 
+	// Run the retention sweeper, triggered once now and again after every time-based rollover
+	go hook.runSweeper()
+	hook.triggerSweep()
+
 	return hook, nil
 }
 
@@ -73,13 +231,13 @@ func (hook *TimeBasedRollingFileHook) rolloverAfter() time.Duration {
 	// Get the current local time
 	t := time.Now().Local()
 
-	oldFileName := t.Format(hook.fileNamePattern)
+	oldFileName := hook.formatPattern(t)
 
 	var t1 time.Time
 	var newFileName string
 
 	t1 = t.Add(time.Minute)
-	newFileName = t1.Format(hook.fileNamePattern)
+	newFileName = hook.formatPattern(t1)
 	if oldFileName != newFileName {
 		// Need to rollover per minute
 
@@ -89,7 +247,7 @@ func (hook *TimeBasedRollingFileHook) rolloverAfter() time.Duration {
 	}
 
 	t1 = t.Add(time.Hour)
-	newFileName = t1.Format(hook.fileNamePattern)
+	newFileName = hook.formatPattern(t1)
 	if oldFileName != newFileName {
 		// Need to rollover per hour
 
@@ -99,7 +257,7 @@ func (hook *TimeBasedRollingFileHook) rolloverAfter() time.Duration {
 	}
 
 	t1 = t.AddDate(0, 0, 1)
-	newFileName = t1.Format(hook.fileNamePattern)
+	newFileName = hook.formatPattern(t1)
 	if oldFileName != newFileName {
 		// Need to rollover per day
 
@@ -109,7 +267,7 @@ func (hook *TimeBasedRollingFileHook) rolloverAfter() time.Duration {
 	}
 
 	t1 = t.AddDate(0, 1, 0)
-	newFileName = t1.Format(hook.fileNamePattern)
+	newFileName = hook.formatPattern(t1)
 	if oldFileName != newFileName {
 		// Need to rollover per month
 
@@ -119,7 +277,7 @@ func (hook *TimeBasedRollingFileHook) rolloverAfter() time.Duration {
 	}
 
 	t1 = t.AddDate(1, 0, 0)
-	newFileName = t1.Format(hook.fileNamePattern)
+	newFileName = hook.formatPattern(t1)
 	if oldFileName != newFileName {
 		// Need to rollover per year
 
@@ -134,7 +292,8 @@ func (hook *TimeBasedRollingFileHook) rolloverAfter() time.Duration {
 // Roll over file.
 // Old file name and error will be returned.
 // If Old file does not exist, empty string will be returned.
-func (hook *TimeBasedRollingFileHook) rolloverFile() (string, error) {
+// The old file's coverage window is returned alongside as ArchiveMetadata.
+func (hook *TimeBasedRollingFileHook) rolloverFile() (string, ArchiveMetadata, error) {
 	// Acquire the lock
 	hook.mu.Lock()
 
@@ -147,6 +306,8 @@ func (hook *TimeBasedRollingFileHook) rolloverFile() (string, error) {
 
 	var oldFileName string
 
+	meta := ArchiveMetadata{FirstTime: hook.firstWriteTime, LastTime: hook.lastWriteTime}
+
 	// Close old file if needed
 	if oldFile != nil {
 		oldFileName = oldFile.Name()
@@ -157,7 +318,7 @@ func (hook *TimeBasedRollingFileHook) rolloverFile() (string, error) {
 	}
 
 	// Get new file name
-	newFileName := time.Now().Local().Format(hook.fileNamePattern)
+	newFileName := hook.formatPattern(time.Now().Local())
 
 	switch strings.ToLower(filepath.Ext(newFileName)) {
 	case GzipSuffix:
@@ -172,26 +333,124 @@ func (hook *TimeBasedRollingFileHook) rolloverFile() (string, error) {
 	err := os.MkdirAll(dir, os.ModeDir|0755)
 
 	if err != nil {
-		return oldFileName, err
+		return oldFileName, meta, err
 	}
 
 	// Create new file
 	newFile, err := os.OpenFile(newFileName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0664)
 
 	if err != nil {
-		return oldFileName, err
+		return oldFileName, meta, err
 	}
 
 	// Switch hook.file to newFile
 	hook.file = newFile
+	hook.currentSize = 0
+	hook.firstWriteTime = time.Time{}
+	hook.lastWriteTime = time.Time{}
+
+	hook.updateSymlink(newFileName)
+
+	return oldFileName, meta, nil
+}
+
+// Roll the current file over because it reached maxSize, without waiting
+// for the next time-based rollover. The current file is shifted to name.1
+// (older backups shifting up to name.2, name.3, ...) and a fresh file is
+// opened under the original name.
+// Caller must hold mu.
+func (hook *TimeBasedRollingFileHook) rolloverForSize() error {
+	fileName := hook.file.Name()
+
+	if err := hook.file.Close(); err != nil {
+		log.Printf("Error on closing file [%s]: %v\n", fileName, err)
+	}
+
+	if err := shiftBackups(fileName, hook.maxFiles); err != nil {
+		return err
+	}
+
+	newFile, err := os.OpenFile(fileName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0664)
+
+	if err != nil {
+		return err
+	}
+
+	hook.file = newFile
+	hook.currentSize = 0
+	hook.firstWriteTime = time.Time{}
+	hook.lastWriteTime = time.Time{}
 
-	return oldFileName, nil
+	hook.updateSymlink(fileName)
+
+	return nil
+}
+
+// updateSymlink atomically points currentSymlink at target, by symlinking to
+// a temp path and renaming over it. A no-op if currentSymlink isn't set, and
+// silently non-fatal (reported via onError) if os.Symlink isn't supported on
+// this platform. Caller must hold mu.
+func (hook *TimeBasedRollingFileHook) updateSymlink(target string) {
+	if hook.currentSymlink == "" {
+		return
+	}
+
+	tmp := hook.currentSymlink + ".tmp"
+
+	os.Remove(tmp)
+
+	if err := os.Symlink(target, tmp); err != nil {
+		hook.reportError(err)
+		return
+	}
+
+	if err := os.Rename(tmp, hook.currentSymlink); err != nil {
+		hook.reportError(err)
+	}
+}
+
+// reportError surfaces err via onError if set, falling back to log.Printf.
+func (hook *TimeBasedRollingFileHook) reportError(err error) {
+	if hook.onError != nil {
+		hook.onError(err)
+		return
+	}
+
+	log.Printf("Error updating current symlink: %v\n", err)
+}
+
+// shiftBackups renames fileName to fileName.1, pushing any existing
+// fileName.1 .. fileName.N-1 up by one. If maxFiles > 0, fileName.maxFiles
+// (the oldest) is deleted first. maxFiles <= 0 means keep all backups.
+func shiftBackups(fileName string, maxFiles int) error {
+	if maxFiles > 0 {
+		oldest := fmt.Sprintf("%s.%d", fileName, maxFiles)
+
+		if _, err := os.Stat(oldest); err == nil {
+			if err := os.Remove(oldest); err != nil {
+				return err
+			}
+		}
+
+		for i := maxFiles - 1; i >= 1; i-- {
+			src := fmt.Sprintf("%s.%d", fileName, i)
+			dst := fmt.Sprintf("%s.%d", fileName, i+1)
+
+			if _, err := os.Stat(src); err == nil {
+				if err := os.Rename(src, dst); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return os.Rename(fileName, fileName+".1")
 }
 
 // Reset timer and archive old file if needed.
 func (hook *TimeBasedRollingFileHook) resetTimer() {
 	// Roll over file
-	oldFileName, err := hook.rolloverFile()
+	oldFileName, meta, err := hook.rolloverFile()
 
 	if err != nil {
 		log.Printf("Error on creating new file: %v\n", err)
@@ -210,19 +469,30 @@ func (hook *TimeBasedRollingFileHook) resetTimer() {
 
 	// Archive old file if needed
 	if oldFileName != "" {
-		go hook.archiveOldFile(oldFileName)
+		go hook.archiveOldFile(oldFileName, meta)
 	}
+
+	// Sweep retention after every time-based rollover
+	hook.triggerSweep()
 }
 
 // Archive old file if needed.
-func (hook *TimeBasedRollingFileHook) archiveOldFile(fileName string) {
-	if archive, ok := Archivers[strings.ToLower(filepath.Ext(hook.fileNamePattern))]; ok {
-		err := archive(fileName)
+func (hook *TimeBasedRollingFileHook) archiveOldFile(fileName string, meta ArchiveMetadata) {
+	compressor, ok := Archivers[strings.ToLower(filepath.Ext(hook.fileNamePattern))]
 
-		if err != nil {
-			log.Printf("Error on archiving file [%s]: %v\n", fileName, err)
+	if !ok {
+		return
+	}
+
+	if hook.compressionLevel != 0 {
+		if ls, ok := compressor.(levelSetter); ok {
+			compressor = ls.withLevel(hook.compressionLevel)
 		}
 	}
+
+	if err := compressAndRemove(fileName, compressor, meta); err != nil {
+		log.Printf("Error on archiving file [%s]: %v\n", fileName, err)
+	}
 }
 
 // Write logrus.Entry to file.
@@ -243,11 +513,28 @@ func (hook *TimeBasedRollingFileHook) write(entry *logrus.Entry) error {
 		}
 
 		// Writing to file
-		_, err = hook.file.Write(b)
+		n, err := hook.file.Write(b)
 
 		if err != nil {
 			return err
 		}
+
+		now := time.Now()
+
+		if hook.firstWriteTime.IsZero() {
+			hook.firstWriteTime = now
+		}
+
+		hook.lastWriteTime = now
+
+		hook.currentSize += int64(n)
+
+		// Roll over on size if configured, sharing mu with the time-based rollover
+		if hook.maxSize > 0 && hook.currentSize >= hook.maxSize {
+			if err := hook.rolloverForSize(); err != nil {
+				log.Printf("Error on size-based rollover: %v\n", err)
+			}
+		}
 	}
 
 	return nil
@@ -255,16 +542,50 @@ func (hook *TimeBasedRollingFileHook) write(entry *logrus.Entry) error {
 
 // Write logrus.Entry.
 func (hook *TimeBasedRollingFileHook) writeEntry() {
+	defer close(hook.writeDone)
+
 	for entry := range hook.queue {
 		// Write logrus.Entry to file.
 		err := hook.write(entry)
 
 		if err != nil {
+			atomic.AddUint64(&hook.writeErrors, 1)
 			log.Printf("Error on writing to file: %v\n", err)
 		}
+
+		atomic.AddUint64(&hook.processed, 1)
 	}
 }
 
+// Close stops the rollover timer, drains the write queue, and terminates the
+// retention sweeper goroutine. The hook must not be used after Close returns.
+func (hook *TimeBasedRollingFileHook) Close() error {
+	if hook.timer != nil {
+		hook.timer.Stop()
+	}
+
+	// Block until every Fire currently sending on hook.queue has returned,
+	// then mark the hook closed so none start sending after we close it.
+	hook.closeMu.Lock()
+	hook.closed = true
+	hook.closeMu.Unlock()
+
+	close(hook.queue)
+	<-hook.writeDone
+
+	close(hook.sweepTrigger)
+	<-hook.sweepDone
+
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+
+	if hook.file != nil {
+		return hook.file.Close()
+	}
+
+	return nil
+}
+
 func (hook *TimeBasedRollingFileHook) Id() string {
 	return hook.id
 }
@@ -274,7 +595,54 @@ func (hook *TimeBasedRollingFileHook) Levels() []logrus.Level {
 }
 
 func (hook *TimeBasedRollingFileHook) Fire(entry *logrus.Entry) error {
-	hook.queue <- entry
+	hook.closeMu.RLock()
+	defer hook.closeMu.RUnlock()
+
+	if hook.closed {
+		atomic.AddUint64(&hook.dropped, 1)
+		return nil
+	}
+
+	switch hook.queuePolicy.kind {
+	case queuePolicyDropNewest:
+		select {
+		case hook.queue <- entry:
+			atomic.AddUint64(&hook.enqueued, 1)
+		default:
+			atomic.AddUint64(&hook.dropped, 1)
+		}
+
+	case queuePolicyDropOldest:
+		select {
+		case hook.queue <- entry:
+			atomic.AddUint64(&hook.enqueued, 1)
+		default:
+			select {
+			case <-hook.queue:
+				atomic.AddUint64(&hook.dropped, 1)
+			default:
+			}
+
+			select {
+			case hook.queue <- entry:
+				atomic.AddUint64(&hook.enqueued, 1)
+			default:
+				atomic.AddUint64(&hook.dropped, 1)
+			}
+		}
+
+	case queuePolicyTimeout:
+		select {
+		case hook.queue <- entry:
+			atomic.AddUint64(&hook.enqueued, 1)
+		case <-time.After(hook.queuePolicy.timeout):
+			atomic.AddUint64(&hook.dropped, 1)
+		}
+
+	default:
+		hook.queue <- entry
+		atomic.AddUint64(&hook.enqueued, 1)
+	}
 
 	return nil
 }
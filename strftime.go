@@ -0,0 +1,140 @@
+package logrus_rollingfile_hook
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PatternDialect selects how fileNamePattern is interpreted.
+type PatternDialect int
+
+const (
+	// DialectAuto picks DialectStrftime if the pattern contains a "%" token,
+	// DialectGo otherwise.
+	DialectAuto PatternDialect = iota
+
+	// DialectGo interprets fileNamePattern as a Go reference-time layout,
+	// e.g. "2006/01/02/15/minute.04.log".
+	DialectGo
+
+	// DialectStrftime interprets fileNamePattern as strftime-style tokens,
+	// e.g. "%Y/%m/%d/%H/minute.%M.log".
+	DialectStrftime
+)
+
+// detectDialect guesses the dialect of pattern by scanning for "%" tokens.
+func detectDialect(pattern string) PatternDialect {
+	if strings.ContainsRune(pattern, '%') {
+		return DialectStrftime
+	}
+
+	return DialectGo
+}
+
+// resolveDialect returns dialect, auto-detecting it from pattern if dialect
+// is DialectAuto.
+func resolveDialect(dialect PatternDialect, pattern string) PatternDialect {
+	if dialect == DialectAuto {
+		return detectDialect(pattern)
+	}
+
+	return dialect
+}
+
+// formatPattern renders fileNamePattern for time t, dispatching to the Go
+// layout formatter or the strftime translator depending on patternDialect.
+func (hook *TimeBasedRollingFileHook) formatPattern(t time.Time) string {
+	if resolveDialect(hook.patternDialect, hook.fileNamePattern) == DialectStrftime {
+		return strftimeFormat(t, hook.fileNamePattern)
+	}
+
+	return t.Format(hook.fileNamePattern)
+}
+
+// patternGlob turns fileNamePattern into a glob matching every file it
+// could ever produce, for either dialect.
+func (hook *TimeBasedRollingFileHook) patternGlob() string {
+	if resolveDialect(hook.patternDialect, hook.fileNamePattern) == DialectStrftime {
+		return strftimeToGlob(hook.fileNamePattern)
+	}
+
+	return layoutToGlob(hook.fileNamePattern)
+}
+
+// strftimeFormat renders a strftime-style pattern for time t. Supports at
+// least %Y %m %d %H %M %S %j %U %w; unrecognized tokens are passed through
+// unchanged.
+func strftimeFormat(t time.Time, pattern string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != '%' || i+1 >= len(pattern) {
+			b.WriteByte(pattern[i])
+			continue
+		}
+
+		i++
+
+		switch pattern[i] {
+		case 'Y':
+			b.WriteString(fmt.Sprintf("%04d", t.Year()))
+		case 'm':
+			b.WriteString(fmt.Sprintf("%02d", int(t.Month())))
+		case 'd':
+			b.WriteString(fmt.Sprintf("%02d", t.Day()))
+		case 'H':
+			b.WriteString(fmt.Sprintf("%02d", t.Hour()))
+		case 'M':
+			b.WriteString(fmt.Sprintf("%02d", t.Minute()))
+		case 'S':
+			b.WriteString(fmt.Sprintf("%02d", t.Second()))
+		case 'j':
+			b.WriteString(fmt.Sprintf("%03d", t.YearDay()))
+		case 'U':
+			b.WriteString(fmt.Sprintf("%02d", weekOfYearSundayStart(t)))
+		case 'w':
+			b.WriteString(strconv.Itoa(int(t.Weekday())))
+		case '%':
+			b.WriteByte('%')
+		default:
+			b.WriteByte('%')
+			b.WriteByte(pattern[i])
+		}
+	}
+
+	return b.String()
+}
+
+// weekOfYearSundayStart returns the week number of the year (00-53), with
+// Sunday as the first day of the week, matching strftime's %U.
+func weekOfYearSundayStart(t time.Time) int {
+	yday := t.YearDay() - 1
+	wday := int(t.Weekday())
+
+	return (yday - wday + 7) / 7
+}
+
+// strftimeToGlob replaces every %X token in a strftime-style pattern with
+// "*", so the result matches every file the pattern could ever produce.
+func strftimeToGlob(pattern string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != '%' || i+1 >= len(pattern) {
+			b.WriteByte(pattern[i])
+			continue
+		}
+
+		i++
+
+		if pattern[i] == '%' {
+			b.WriteByte('%')
+		} else {
+			b.WriteByte('*')
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,78 @@
+package logrus_rollingfile_hook
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStrftimeFormat(t *testing.T) {
+	// 2015-12-31 23:59:01, a Thursday, day-of-year 365.
+	at := time.Date(2015, time.December, 31, 23, 59, 1, 0, time.UTC)
+
+	cases := []struct {
+		pattern string
+		want    string
+	}{
+		{"%Y/%m/%d/%H/minute.%M.log", "2015/12/31/23/minute.59.log"},
+		{"%Y-%m-%dT%H:%M:%S", "2015-12-31T23:59:01"},
+		{"%j", "365"},
+		{"%w", "4"},
+		{"100%% done", "100% done"},
+		{"%Q", "%Q"}, // unrecognized token passed through unchanged
+	}
+
+	for _, c := range cases {
+		if got := strftimeFormat(at, c.pattern); got != c.want {
+			t.Errorf("strftimeFormat(%q) = %q, want %q", c.pattern, got, c.want)
+		}
+	}
+}
+
+func TestDetectDialect(t *testing.T) {
+	cases := []struct {
+		pattern string
+		want    PatternDialect
+	}{
+		{"%Y/%m/%d/%H/minute.%M.log", DialectStrftime},
+		{"2006/01/02/15/minute.04.log", DialectGo},
+	}
+
+	for _, c := range cases {
+		if got := detectDialect(c.pattern); got != c.want {
+			t.Errorf("detectDialect(%q) = %v, want %v", c.pattern, got, c.want)
+		}
+	}
+}
+
+func TestResolveDialect(t *testing.T) {
+	if got := resolveDialect(DialectGo, "%Y/%m.log"); got != DialectGo {
+		t.Errorf("resolveDialect(DialectGo, ...) = %v, want forced DialectGo", got)
+	}
+
+	if got := resolveDialect(DialectAuto, "%Y/%m.log"); got != DialectStrftime {
+		t.Errorf("resolveDialect(DialectAuto, strftime pattern) = %v, want DialectStrftime", got)
+	}
+
+	if got := resolveDialect(DialectAuto, "2006/01.log"); got != DialectGo {
+		t.Errorf("resolveDialect(DialectAuto, go pattern) = %v, want DialectGo", got)
+	}
+}
+
+func TestFormatPatternDispatch(t *testing.T) {
+	at := time.Date(2015, time.December, 31, 23, 59, 1, 0, time.UTC)
+
+	hook := &TimeBasedRollingFileHook{fileNamePattern: "%Y/%m/%d/%H/minute.%M.log"}
+
+	if got, want := hook.formatPattern(at), "2015/12/31/23/minute.59.log"; got != want {
+		t.Errorf("formatPattern (auto-detected strftime) = %q, want %q", got, want)
+	}
+
+	hook = &TimeBasedRollingFileHook{
+		fileNamePattern: "2006/01/02/15/minute.04.log",
+		patternDialect:  DialectGo,
+	}
+
+	if got, want := hook.formatPattern(at), "2015/12/31/23/minute.59.log"; got != want {
+		t.Errorf("formatPattern (forced Go layout) = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,75 @@
+package logrus_rollingfile_hook
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TestCurrentSymlink checks that CurrentSymlink is created pointing at the
+// active file once the hook has opened it, per chunk0-5.
+func TestCurrentSymlink(t *testing.T) {
+	dir := t.TempDir()
+	pattern := filepath.Join(dir, "app.log")
+	symlink := filepath.Join(dir, "current.log")
+
+	// t.TempDir() embeds numbered path components (e.g. "/001") that Go's
+	// reference-time layout would otherwise parse as format tokens (month
+	// "01", etc), mangling the written path. Force DialectStrftime so
+	// pattern is only rewritten where it contains "%", leaving the literal
+	// temp dir path intact.
+	hook, err := NewTimeBasedRollingFileHook("symlink-test",
+		[]logrus.Level{logrus.InfoLevel},
+		&logrus.JSONFormatter{},
+		pattern,
+		WithCurrentSymlink(symlink),
+		WithPatternDialect(DialectStrftime))
+
+	if err != nil {
+		t.Fatalf("NewTimeBasedRollingFileHook: %v", err)
+	}
+
+	defer hook.Close()
+
+	target, err := os.Readlink(symlink)
+
+	if err != nil {
+		t.Fatalf("Readlink: %v", err)
+	}
+
+	if target != pattern {
+		t.Fatalf("symlink points to %q, want %q", target, pattern)
+	}
+}
+
+// TestCurrentSymlinkOnError checks that a failure updating the symlink is
+// reported via OnError instead of only being logged, per chunk0-5.
+func TestCurrentSymlinkOnError(t *testing.T) {
+	dir := t.TempDir()
+	pattern := filepath.Join(dir, "app.log")
+
+	// A symlink path under a directory that doesn't exist makes os.Symlink
+	// fail every time updateSymlink runs.
+	badSymlink := filepath.Join(dir, "missing-dir", "current.log")
+
+	var reported error
+
+	hook, err := NewTimeBasedRollingFileHook("symlink-error-test",
+		[]logrus.Level{logrus.InfoLevel},
+		&logrus.JSONFormatter{},
+		pattern,
+		WithCurrentSymlink(badSymlink),
+		WithOnError(func(e error) { reported = e }))
+
+	if err != nil {
+		t.Fatalf("NewTimeBasedRollingFileHook: %v", err)
+	}
+
+	defer hook.Close()
+
+	if reported == nil {
+		t.Fatal("expected OnError to be called with the symlink failure")
+	}
+}